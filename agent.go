@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+const (
+	rcMarkerStart = "# >>> just_setup_git ssh-agent >>>"
+	rcMarkerEnd   = "# <<< just_setup_git ssh-agent <<<"
+)
+
+// startSSHAgent launches `ssh-agent -s`, parses SSH_AUTH_SOCK/SSH_AGENT_PID
+// out of its output, and exports them into the current process so the
+// `ssh-add` call that follows succeeds on first run instead of falling back
+// to whatever agent (if any) the parent shell already had.
+func startSSHAgent() error {
+	out, err := exec.Command("ssh-agent", "-s").Output()
+	if err != nil {
+		return fmt.Errorf("starting ssh-agent: %w", err)
+	}
+
+	sockRe := regexp.MustCompile(`SSH_AUTH_SOCK=([^;]+);`)
+	pidRe := regexp.MustCompile(`SSH_AGENT_PID=([^;]+);`)
+
+	sock := sockRe.FindSubmatch(out)
+	pid := pidRe.FindSubmatch(out)
+	if sock == nil || pid == nil {
+		return fmt.Errorf("could not parse ssh-agent output")
+	}
+
+	os.Setenv("SSH_AUTH_SOCK", string(sock[1]))
+	os.Setenv("SSH_AGENT_PID", string(pid[1]))
+
+	return nil
+}
+
+// shellRCPath maps $SHELL to the rc file that shell sources on login.
+func shellRCPath(homeDir string) (shell, rcPath string, ok bool) {
+	shell = filepath.Base(os.Getenv("SHELL"))
+	switch shell {
+	case "bash":
+		return shell, filepath.Join(homeDir, ".bashrc"), true
+	case "zsh":
+		return shell, filepath.Join(homeDir, ".zshrc"), true
+	case "fish":
+		return shell, filepath.Join(homeDir, ".config", "fish", "config.fish"), true
+	case "nu":
+		return shell, filepath.Join(homeDir, ".config", "nushell", "config.nu"), true
+	default:
+		return shell, "", false
+	}
+}
+
+// agentRCBlock returns the shell-specific snippet that starts ssh-agent and
+// loads the configured key, wrapped in the marker comments that make
+// appendMarkedBlock idempotent.
+func agentRCBlock(shell string) string {
+	var body string
+	switch shell {
+	case "fish":
+		body = fmt.Sprintf("ssh-agent -c | source\nssh-add %s", sshPath)
+	case "nu":
+		body = fmt.Sprintf("^ssh-agent -s | lines | parse \"{key}={value}; export {_}\" | load-env\nssh-add %s", sshPath)
+	default: // bash, zsh
+		body = fmt.Sprintf("eval \"$(ssh-agent -s)\"\nssh-add %s", sshPath)
+	}
+	return fmt.Sprintf("%s\n%s\n%s\n", rcMarkerStart, body, rcMarkerEnd)
+}
+
+// appendMarkedBlock writes block into path, replacing any previous block
+// between rcMarkerStart/rcMarkerEnd so re-running the tool doesn't keep
+// appending duplicate entries.
+func appendMarkedBlock(path, block string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content := string(existing)
+	if start := strings.Index(content, rcMarkerStart); start != -1 {
+		end := strings.Index(content, rcMarkerEnd)
+		if end != -1 {
+			// block already ends in its own "\n"; the old block's trailing
+			// "\n" would otherwise accumulate as a growing blank line on
+			// every re-run.
+			suffix := strings.TrimPrefix(content[end+len(rcMarkerEnd):], "\n")
+			content = content[:start] + block + suffix
+			return os.WriteFile(path, []byte(content), 0o644)
+		}
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += block
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// macOSSSHConfigBlock returns the `~/.ssh/config` entry macOS uses to make
+// the Keychain remember the key's passphrase across reboots.
+func macOSSSHConfigBlock() string {
+	body := fmt.Sprintf("Host *\n  AddKeysToAgent yes\n  UseKeychain yes\n  IdentityFile %s", sshPath)
+	return fmt.Sprintf("%s\n%s\n%s\n", rcMarkerStart, body, rcMarkerEnd)
+}
+
+// offerShellIntegration asks the user whether to wire ssh-agent into their
+// shell startup (or, on macOS, into ~/.ssh/config) so the key is loaded
+// automatically in future sessions.
+func offerShellIntegration(homeDir string) error {
+	var install bool
+
+	if runtime.GOOS == "darwin" {
+		confirm := huh.NewConfirm().
+			Title("Add this key to ~/.ssh/config so macOS Keychain remembers it?").
+			Value(&install)
+		if err := confirm.Run(); err != nil {
+			return err
+		}
+		if !install {
+			return nil
+		}
+
+		path := filepath.Join(homeDir, ".ssh", "config")
+		if err := appendMarkedBlock(path, macOSSSHConfigBlock()); err != nil {
+			return err
+		}
+		if err := os.Chmod(path, 0o600); err != nil {
+			return err
+		}
+		success("Updated " + path)
+		return nil
+	}
+
+	shell, rcPath, ok := shellRCPath(homeDir)
+	if !ok {
+		warning("Could not detect a supported shell from $SHELL, skipping rc integration")
+		return nil
+	}
+
+	rcRel, _ := filepath.Rel(homeDir, rcPath)
+	confirm := huh.NewConfirm().
+		Title(fmt.Sprintf("Add ssh-agent startup to ~/%s?", rcRel)).
+		Description("Starts ssh-agent and loads your SSH key in every new shell, so its passphrase is asked once per shell").
+		Value(&install)
+	if err := confirm.Run(); err != nil {
+		return err
+	}
+	if !install {
+		return nil
+	}
+
+	if err := appendMarkedBlock(rcPath, agentRCBlock(shell)); err != nil {
+		return err
+	}
+	success("Updated " + rcPath)
+
+	return nil
+}
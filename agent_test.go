@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppendMarkedBlock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rc")
+
+	block := rcMarkerStart + "\nfirst\n" + rcMarkerEnd + "\n"
+	if err := appendMarkedBlock(path, block); err != nil {
+		t.Fatalf("first append: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != block {
+		t.Fatalf("got %q, want %q", got, block)
+	}
+}
+
+func TestAppendMarkedBlockPreservesSurroundingContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rc")
+
+	initial := "export PATH=$PATH:/usr/local/bin\n"
+	if err := os.WriteFile(path, []byte(initial), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	block := rcMarkerStart + "\nfirst\n" + rcMarkerEnd + "\n"
+	if err := appendMarkedBlock(path, block); err != nil {
+		t.Fatalf("append to existing file: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), initial) {
+		t.Fatalf("expected existing content preserved, got %q", got)
+	}
+	if !strings.HasSuffix(string(got), block) {
+		t.Fatalf("expected block appended, got %q", got)
+	}
+}
+
+func TestAppendMarkedBlockIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rc")
+
+	first := rcMarkerStart + "\nfirst\n" + rcMarkerEnd + "\n"
+	if err := appendMarkedBlock(path, first); err != nil {
+		t.Fatalf("first append: %v", err)
+	}
+
+	second := rcMarkerStart + "\nsecond\n" + rcMarkerEnd + "\n"
+	if err := appendMarkedBlock(path, second); err != nil {
+		t.Fatalf("second append: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != second {
+		t.Fatalf("re-running should replace the old block, not duplicate it: got %q", got)
+	}
+	if strings.Count(string(got), rcMarkerStart) != 1 {
+		t.Fatalf("expected exactly one marker block, got %q", got)
+	}
+}
+
+func TestShellRCPath(t *testing.T) {
+	home := "/home/test"
+	tests := []struct {
+		shell   string
+		wantOK  bool
+		wantEnd string
+	}{
+		{"bash", true, ".bashrc"},
+		{"zsh", true, ".zshrc"},
+		{"fish", true, filepath.Join(".config", "fish", "config.fish")},
+		{"nu", true, filepath.Join(".config", "nushell", "config.nu")},
+		{"tcsh", false, ""},
+		{"", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			t.Setenv("SHELL", "/bin/"+tt.shell)
+			shell, rcPath, ok := shellRCPath(home)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if shell != tt.shell {
+				t.Fatalf("shell = %q, want %q", shell, tt.shell)
+			}
+			if !strings.HasSuffix(rcPath, tt.wantEnd) {
+				t.Fatalf("rcPath = %q, want suffix %q", rcPath, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// resetConfigGlobals clears every package-level var resolveNonInteractive
+// touches, so subtests don't leak state into each other.
+func resetConfigGlobals() {
+	gitContext, username, email, sshPath = "", "", "", ""
+	ignoreSSH, signing, useExistingKey = false, false, false
+	flagGlobal, flagName, flagEmail, flagSSHKey, flagSign, flagNoSSH, flagConfigPath = false, "", "", "", "", false, ""
+}
+
+func TestResolveNonInteractiveConfigFileOnly(t *testing.T) {
+	resetConfigGlobals()
+	defer resetConfigGlobals()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	yamlContent := "global: true\nname: Config Name\nemail: config@example.com\nno_ssh: true\nsign: ssh\n"
+	if err := os.WriteFile(path, []byte(yamlContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	flagConfigPath = path
+
+	complete, err := resolveNonInteractive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete {
+		t.Fatal("expected config file alone to be complete")
+	}
+	if gitContext != "--global" || username != "Config Name" || email != "config@example.com" {
+		t.Fatalf("config file values not applied: gitContext=%q username=%q email=%q", gitContext, username, email)
+	}
+	if !ignoreSSH || !signing {
+		t.Fatalf("expected no_ssh/sign from config file applied: ignoreSSH=%v signing=%v", ignoreSSH, signing)
+	}
+}
+
+func TestResolveNonInteractiveFlagsOverrideConfigFile(t *testing.T) {
+	resetConfigGlobals()
+	defer resetConfigGlobals()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	yamlContent := "global: true\nname: Config Name\nemail: config@example.com\nsign: ssh\n"
+	if err := os.WriteFile(path, []byte(yamlContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	flagConfigPath = path
+	flagName = "Flag Name"
+	flagSign = "none"
+
+	_, err := resolveNonInteractive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if username != "Flag Name" {
+		t.Fatalf("expected flag to override config file name, got %q", username)
+	}
+	if email != "config@example.com" {
+		t.Fatalf("expected config file email to survive when no flag set, got %q", email)
+	}
+	if signing {
+		t.Fatal("expected --sign=none flag to override sign: ssh from config file")
+	}
+}
+
+func TestResolveNonInteractiveIncomplete(t *testing.T) {
+	resetConfigGlobals()
+	defer resetConfigGlobals()
+
+	dir := t.TempDir()
+	flagConfigPath = filepath.Join(dir, "does-not-exist.yaml")
+	flagName = "Only Name"
+
+	complete, err := resolveNonInteractive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if complete {
+		t.Fatal("expected missing email/gitContext to leave setup incomplete")
+	}
+}
+
+func TestResolveNonInteractiveDefaultsSSHPath(t *testing.T) {
+	resetConfigGlobals()
+	defer resetConfigGlobals()
+
+	dir := t.TempDir()
+	flagConfigPath = filepath.Join(dir, "does-not-exist.yaml")
+
+	if _, err := resolveNonInteractive(); err != nil {
+		t.Fatal(err)
+	}
+	if sshPath == "" {
+		t.Fatal("expected sshPath to default when SSH isn't ignored")
+	}
+}
+
+func TestResolveNonInteractiveNoSSHPathDefaultWhenIgnored(t *testing.T) {
+	resetConfigGlobals()
+	defer resetConfigGlobals()
+
+	dir := t.TempDir()
+	flagConfigPath = filepath.Join(dir, "does-not-exist.yaml")
+	flagNoSSH = true
+
+	if _, err := resolveNonInteractive(); err != nil {
+		t.Fatal(err)
+	}
+	if sshPath != "" {
+		t.Fatalf("expected no default ssh path when --no-ssh is set, got %q", sshPath)
+	}
+}
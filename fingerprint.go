@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SSHKey describes a generated or selected key, as reported by `ssh-keygen -l`.
+type SSHKey struct {
+	Path        string
+	Bits        int
+	Fingerprint string
+	Comment     string
+	Type        string
+}
+
+var currentKey SSHKey
+
+// loadCurrentKey fingerprints sshPath, stores it on currentKey for display in
+// almostDone, and warns if the fingerprint is already trusted in the local
+// authorized_keys file.
+func loadCurrentKey() error {
+	key, err := fingerprintKeyFile(sshPath + ".pub")
+	if err != nil {
+		return err
+	}
+	currentKey = key
+	success(fmt.Sprintf("Key fingerprint: %s (%s, %d bits)", key.Fingerprint, key.Type, key.Bits))
+
+	authorizedKeys := filepath.Join(filepath.Dir(sshPath), "authorized_keys")
+	dup, err := localDuplicateFingerprint(authorizedKeys, key.Fingerprint)
+	if err != nil {
+		return err
+	}
+	if dup {
+		warning("This key's fingerprint is already present in " + authorizedKeys)
+	}
+
+	return nil
+}
+
+// fingerprintKeyFile runs `ssh-keygen -lf` against a public key file and
+// parses its "<bits> <fingerprint> <comment> (<type>)" output.
+func fingerprintKeyFile(pubPath string) (SSHKey, error) {
+	out, err := exec.Command("ssh-keygen", "-lf", pubPath).Output()
+	if err != nil {
+		return SSHKey{}, fmt.Errorf("reading fingerprint of %s: %w", pubPath, err)
+	}
+	key, err := parseFingerprintLine(string(out))
+	if err != nil {
+		return SSHKey{}, err
+	}
+	key.Path = strings.TrimSuffix(pubPath, ".pub")
+	return key, nil
+}
+
+// fingerprintKeyString feeds a raw `authorized_keys`-style line to
+// `ssh-keygen -lf -` so remote and local keys can be fingerprinted the same
+// way a freshly generated one is.
+func fingerprintKeyString(line string) (SSHKey, error) {
+	cmd := exec.Command("ssh-keygen", "-lf", "-")
+	cmd.Stdin = strings.NewReader(line)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return SSHKey{}, fmt.Errorf("reading fingerprint: %w", err)
+	}
+	return parseFingerprintLine(string(out))
+}
+
+func parseFingerprintLine(line string) (SSHKey, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return SSHKey{}, fmt.Errorf("unexpected ssh-keygen output: %q", line)
+	}
+
+	bits, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return SSHKey{}, fmt.Errorf("unexpected ssh-keygen output: %q", line)
+	}
+
+	return SSHKey{
+		Bits:        bits,
+		Fingerprint: fields[1],
+		Comment:     strings.Join(fields[2:len(fields)-1], " "),
+		Type:        strings.Trim(fields[len(fields)-1], "()"),
+	}, nil
+}
+
+// remoteDuplicateFingerprint reports whether fingerprint is already present
+// in the key list returned by list (a forge's ListAuthKeys or
+// ListSigningKeys), to avoid creating a duplicate entry. Auth and signing
+// keys are separate records on every forge, so callers must pass the list
+// matching the upload they're about to make.
+func remoteDuplicateFingerprint(list func(token string) ([]string, error), token, fingerprint string) (bool, error) {
+	keys, err := list(token)
+	if err != nil {
+		return false, err
+	}
+	for _, raw := range keys {
+		key, err := fingerprintKeyString(raw)
+		if err != nil {
+			continue
+		}
+		if key.Fingerprint == fingerprint {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// localDuplicateFingerprint reports whether fingerprint already appears in
+// path (an authorized_keys-style file), so a regenerate doesn't silently
+// shadow a key that's already trusted somewhere.
+func localDuplicateFingerprint(path, fingerprint string) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := fingerprintKeyString(line)
+		if err != nil {
+			continue
+		}
+		if key.Fingerprint == fingerprint {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
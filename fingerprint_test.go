@@ -0,0 +1,172 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFingerprintLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    SSHKey
+		wantErr bool
+	}{
+		{
+			name: "ed25519",
+			line: "256 SHA256:abcDEF123/+xyz== comment here (ED25519)\n",
+			want: SSHKey{Bits: 256, Fingerprint: "SHA256:abcDEF123/+xyz==", Comment: "comment here", Type: "ED25519"},
+		},
+		{
+			name: "rsa no comment",
+			line: "3072 SHA256:zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz no comment (RSA)\n",
+			want: SSHKey{Bits: 3072, Fingerprint: "SHA256:zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", Comment: "no comment", Type: "RSA"},
+		},
+		{
+			name:    "too few fields",
+			line:    "256 SHA256:abc\n",
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric bits",
+			line:    "abc SHA256:xyz comment (ED25519)\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			line:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFingerprintLine(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// genTestKey generates an ed25519 keypair in dir and returns the public key
+// path, skipping the test if ssh-keygen isn't available.
+func genTestKey(t *testing.T, dir, name string) string {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	path := filepath.Join(dir, name)
+	cmd := exec.Command("ssh-keygen", "-t", "ed25519", "-f", path, "-N", "", "-C", "test@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v\n%s", err, out)
+	}
+	return path + ".pub"
+}
+
+func TestFingerprintKeyFileAndString(t *testing.T) {
+	dir := t.TempDir()
+	pubPath := genTestKey(t, dir, "id_test")
+
+	fromFile, err := fingerprintKeyFile(pubPath)
+	if err != nil {
+		t.Fatalf("fingerprintKeyFile: %v", err)
+	}
+	if fromFile.Fingerprint == "" || fromFile.Type != "ED25519" {
+		t.Fatalf("unexpected key from file: %+v", fromFile)
+	}
+
+	pub, err := os.ReadFile(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromString, err := fingerprintKeyString(string(pub))
+	if err != nil {
+		t.Fatalf("fingerprintKeyString: %v", err)
+	}
+
+	if fromString.Fingerprint != fromFile.Fingerprint {
+		t.Fatalf("fingerprint mismatch: file=%q string=%q", fromFile.Fingerprint, fromString.Fingerprint)
+	}
+}
+
+func TestLocalDuplicateFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	pubPath := genTestKey(t, dir, "id_test")
+
+	pub, err := os.ReadFile(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := fingerprintKeyString(string(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizedKeys := filepath.Join(dir, "authorized_keys")
+
+	dup, err := localDuplicateFingerprint(authorizedKeys, key.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dup {
+		t.Fatal("expected no duplicate before authorized_keys exists")
+	}
+
+	if err := os.WriteFile(authorizedKeys, pub, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dup, err = localDuplicateFingerprint(authorizedKeys, key.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dup {
+		t.Fatal("expected duplicate after authorized_keys contains the key")
+	}
+}
+
+func TestRemoteDuplicateFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	pubPath := genTestKey(t, dir, "id_test")
+	pub, err := os.ReadFile(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := fingerprintKeyString(string(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listWith := func(lines []string) func(string) ([]string, error) {
+		return func(string) ([]string, error) { return lines, nil }
+	}
+
+	dup, err := remoteDuplicateFingerprint(listWith(nil), "token", key.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dup {
+		t.Fatal("expected no duplicate against an empty list")
+	}
+
+	dup, err = remoteDuplicateFingerprint(listWith([]string{string(pub)}), "token", key.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dup {
+		t.Fatal("expected duplicate when the list contains the same key")
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/charmbracelet/huh"
+)
+
+var provider string
+
+// KeyUploader lets a forge (GitHub, Gitea, GitLab, ...) accept a public key
+// on behalf of the authenticated user. Implementations should treat a
+// "key already exists" response from the forge as success, not an error.
+type KeyUploader interface {
+	// UploadAuthKey registers pubKey as an authentication key.
+	UploadAuthKey(token, title, pubKey string) error
+	// UploadSigningKey registers pubKey as a commit signing key.
+	UploadSigningKey(token, title, pubKey string) error
+	// ListAuthKeys returns the raw public keys already registered as
+	// authentication keys for the authenticated user, so callers can check
+	// for duplicates before upload.
+	ListAuthKeys(token string) ([]string, error)
+	// ListSigningKeys returns the raw public keys already registered as
+	// signing keys for the authenticated user. Forges keep auth and signing
+	// keys in separate records, so this must be checked independently of
+	// ListAuthKeys.
+	ListSigningKeys(token string) ([]string, error)
+}
+
+func uploaderFor(name string) (KeyUploader, error) {
+	switch name {
+	case "github":
+		return githubUploader{}, nil
+	case "gitea":
+		return giteaUploader{}, nil
+	case "gitlab":
+		return gitlabUploader{}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+}
+
+// doUploadRequest POSTs body to url with a bearer token and treats 201 and
+// 422 (the forge's way of saying "this key is already registered") as
+// success, returning an error for anything else.
+func doUploadRequest(url, token string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return nil
+	case http.StatusUnprocessableEntity:
+		warning("Key already registered, skipping")
+		return nil
+	default:
+		return fmt.Errorf("unexpected status %s uploading key", resp.Status)
+	}
+}
+
+// doListRequest GETs url with a bearer token and unmarshals the response
+// into v.
+func doListRequest(url, token string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s listing keys", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+type githubUploader struct{}
+
+func (githubUploader) UploadAuthKey(token, title, pubKey string) error {
+	body, err := json.Marshal(map[string]string{"title": title, "key": pubKey})
+	if err != nil {
+		return err
+	}
+	return doUploadRequest("https://api.github.com/user/keys", token, body)
+}
+
+func (githubUploader) UploadSigningKey(token, title, pubKey string) error {
+	body, err := json.Marshal(map[string]string{"title": title, "key": pubKey})
+	if err != nil {
+		return err
+	}
+	return doUploadRequest("https://api.github.com/user/ssh_signing_keys", token, body)
+}
+
+func (githubUploader) ListAuthKeys(token string) ([]string, error) {
+	var keys []struct {
+		Key string `json:"key"`
+	}
+	if err := doListRequest("https://api.github.com/user/keys", token, &keys); err != nil {
+		return nil, err
+	}
+	raw := make([]string, len(keys))
+	for i, k := range keys {
+		raw[i] = k.Key
+	}
+	return raw, nil
+}
+
+func (githubUploader) ListSigningKeys(token string) ([]string, error) {
+	var keys []struct {
+		Key string `json:"key"`
+	}
+	if err := doListRequest("https://api.github.com/user/ssh_signing_keys", token, &keys); err != nil {
+		return nil, err
+	}
+	raw := make([]string, len(keys))
+	for i, k := range keys {
+		raw[i] = k.Key
+	}
+	return raw, nil
+}
+
+type giteaUploader struct{}
+
+func (giteaUploader) UploadAuthKey(token, title, pubKey string) error {
+	body, err := json.Marshal(map[string]any{"title": title, "key": pubKey, "read_only": false})
+	if err != nil {
+		return err
+	}
+	return doUploadRequest("https://gitea.com/api/v1/user/keys", token, body)
+}
+
+func (giteaUploader) UploadSigningKey(token, title, pubKey string) error {
+	// Gitea has no separate signing key endpoint; a registered auth key
+	// doubles as a verification key for ssh-signed commits.
+	return giteaUploader{}.UploadAuthKey(token, title, pubKey)
+}
+
+func (giteaUploader) ListAuthKeys(token string) ([]string, error) {
+	var keys []struct {
+		Key string `json:"key"`
+	}
+	if err := doListRequest("https://gitea.com/api/v1/user/keys", token, &keys); err != nil {
+		return nil, err
+	}
+	raw := make([]string, len(keys))
+	for i, k := range keys {
+		raw[i] = k.Key
+	}
+	return raw, nil
+}
+
+func (giteaUploader) ListSigningKeys(token string) ([]string, error) {
+	// Gitea has no separate signing key record; the auth key list is it.
+	return giteaUploader{}.ListAuthKeys(token)
+}
+
+type gitlabUploader struct{}
+
+func (gitlabUploader) UploadAuthKey(token, title, pubKey string) error {
+	body, err := json.Marshal(map[string]string{"title": title, "key": pubKey})
+	if err != nil {
+		return err
+	}
+	return doUploadRequest("https://gitlab.com/api/v4/user/keys", token, body)
+}
+
+func (gitlabUploader) UploadSigningKey(token, title, pubKey string) error {
+	body, err := json.Marshal(map[string]string{"title": title, "key": pubKey, "usage_type": "signing"})
+	if err != nil {
+		return err
+	}
+	return doUploadRequest("https://gitlab.com/api/v4/user/keys", token, body)
+}
+
+// gitlabKey is shared by ListAuthKeys and ListSigningKeys since GitLab keeps
+// both kinds in the same /user/keys list, distinguished by usage_type.
+type gitlabKey struct {
+	Key       string `json:"key"`
+	UsageType string `json:"usage_type"`
+}
+
+func (gitlabUploader) listKeys(token, usageType string) ([]string, error) {
+	var keys []gitlabKey
+	if err := doListRequest("https://gitlab.com/api/v4/user/keys", token, &keys); err != nil {
+		return nil, err
+	}
+	var raw []string
+	for _, k := range keys {
+		if k.UsageType == usageType || k.UsageType == "auth_and_signing" {
+			raw = append(raw, k.Key)
+		}
+	}
+	return raw, nil
+}
+
+func (g gitlabUploader) ListAuthKeys(token string) ([]string, error) {
+	return g.listKeys(token, "auth")
+}
+
+func (g gitlabUploader) ListSigningKeys(token string) ([]string, error) {
+	return g.listKeys(token, "signing")
+}
+
+// tokenFromEnv returns a PAT from GITHUB_TOKEN/GH_TOKEN so CI and scripted
+// setups don't have to prompt for one.
+func tokenFromEnv() string {
+	if tok := os.Getenv("GITHUB_TOKEN"); tok != "" {
+		return tok
+	}
+	return os.Getenv("GH_TOKEN")
+}
+
+// uploadKeys optionally uploads the just-configured SSH key (and, if signing
+// was set up, registers it as a signing key too) to the chosen forge so
+// users don't have to hand-paste it in via the web UI.
+func uploadKeys() error {
+	uploader, err := uploaderFor(provider)
+	if err != nil {
+		return err
+	}
+
+	var upload bool
+	var token = tokenFromEnv()
+	var title string
+
+	confirm := huh.NewConfirm().
+		Title(fmt.Sprintf("Upload SSH key to %s?", provider)).
+		Description("Requires a personal access token with the `admin:public_key` / `write:ssh_signing_key` scopes").
+		Value(&upload)
+
+	if err := confirm.Run(); err != nil {
+		return err
+	}
+	if !upload {
+		return nil
+	}
+
+	fields := []huh.Field{
+		huh.NewInput().
+			Title("Key title").
+			Placeholder("e.g. this machine's hostname").
+			Value(&title).
+			Validate(func(str string) error {
+				if str == "" {
+					return errors.New("Title Required")
+				}
+				return nil
+			}),
+	}
+	if token == "" {
+		fields = append(fields, huh.NewInput().
+			Title("Personal access token").
+			Description("Falls back to $GITHUB_TOKEN / $GH_TOKEN if set").
+			EchoMode(huh.EchoModePassword).
+			Value(&token).
+			Validate(func(str string) error {
+				if str == "" {
+					return errors.New("Token Required")
+				}
+				return nil
+			}))
+	}
+
+	if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+		return err
+	}
+
+	pub, err := os.ReadFile(sshPath + ".pub")
+	if err != nil {
+		return err
+	}
+
+	if !ignoreSSH {
+		alreadyRegistered, err := remoteDuplicateFingerprint(uploader.ListAuthKeys, token, currentKey.Fingerprint)
+		if err != nil {
+			return err
+		}
+		if alreadyRegistered {
+			warning("This key is already registered with " + provider + " as an auth key, skipping upload")
+		} else if err := uploader.UploadAuthKey(token, title, string(pub)); err != nil {
+			return err
+		} else {
+			success("Successfully uploaded SSH key to " + provider)
+		}
+	}
+
+	if signing {
+		alreadyRegistered, err := remoteDuplicateFingerprint(uploader.ListSigningKeys, token, currentKey.Fingerprint)
+		if err != nil {
+			return err
+		}
+		if alreadyRegistered {
+			warning("This key is already registered with " + provider + " as a signing key, skipping upload")
+		} else if err := uploader.UploadSigningKey(token, title, string(pub)); err != nil {
+			return err
+		} else {
+			success("Successfully uploaded SSH signing key to " + provider)
+		}
+	}
+
+	return nil
+}
@@ -2,24 +2,30 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"os/user"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 )
 
+const generateNewKey = "__generate__"
+
 var (
-	gitContext string
-	username   string
-	email      string
-	ignoreSSH  bool
-	sshPath    string
-	signing    bool
+	gitContext     string
+	username       string
+	email          string
+	ignoreSSH      bool
+	sshPath        string
+	signing        bool
+	sshKeyChoice   string
+	useExistingKey bool
 )
 
 var (
@@ -30,7 +36,18 @@ var (
 )
 
 func main() {
-	if err := promptForm(); err != nil {
+	flag.StringVar(&provider, "provider", "github", "forge to upload SSH keys to (github, gitea, gitlab)")
+	registerConfigFlags()
+	flag.Parse()
+
+	complete, err := resolveNonInteractive()
+	if err != nil {
+		panic(err)
+	}
+
+	if complete {
+		success("Using configuration from flags/config file, skipping the setup form")
+	} else if err := promptForm(); err != nil {
 		panic(err)
 	}
 
@@ -49,7 +66,12 @@ func main() {
 			panic(err)
 		}
 	}
-	sshPath = "/home/mason/.ssh/id_ed25519"
+
+	if !ignoreSSH || signing {
+		if err := uploadKeys(); err != nil {
+			panic(err)
+		}
+	}
 
 	if !ignoreSSH || signing {
 		almostDone()
@@ -75,11 +97,46 @@ func panic(err error) {
 	log.Fatal(err)
 }
 
+// discoverSSHKeys scans homeDir/.ssh for private keys of common types whose
+// matching .pub file also exists, mirroring the default-identity discovery
+// most SSH tooling does.
+func discoverSSHKeys(homeDir string) []string {
+	var found []string
+	for _, name := range []string{"id_ed25519", "id_rsa", "id_ecdsa"} {
+		path := filepath.Join(homeDir, ".ssh", name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if _, err := os.Stat(path + ".pub"); err != nil {
+			continue
+		}
+		found = append(found, path)
+	}
+	return found
+}
+
 // Ask user questions
 func promptForm() (err error) {
 	// ssh path inferencing
 	currentUser, err := user.Current()
-	sshPath = fmt.Sprintf("%s/.ssh/id_ed25519", currentUser.HomeDir)
+	if err != nil {
+		return err
+	}
+	if sshPath == "" {
+		sshPath = fmt.Sprintf("%s/.ssh/id_ed25519", currentUser.HomeDir)
+	}
+	sshKeyChoice = generateNewKey
+
+	existingKeys := discoverSSHKeys(currentUser.HomeDir)
+	keyOptions := []huh.Option[string]{huh.NewOption("Generate new key", generateNewKey)}
+	for _, key := range existingKeys {
+		keyOptions = append(keyOptions, huh.NewOption(key, key))
+	}
+
+	// A key (new or existing) is needed whenever SSH itself is wanted, or
+	// when only signing was requested - so the picker/path groups below
+	// must key off both, not just `ignoreSSH`.
+	needsKey := func() bool { return !ignoreSSH || signing }
 
 	form := huh.
 		NewForm(
@@ -131,12 +188,9 @@ func promptForm() (err error) {
 						huh.NewOption("No", true),
 					),
 
-				// file for SSH key
-				huh.NewInput().
-					Title("File in which to save SSH key?").
-					Value(&sshPath),
-
 				// ask if want signing
+				// Asked here, before the key picker/path groups, so those
+				// groups' hide funcs can see the final value of `signing`.
 				huh.NewSelect[bool]().
 					Title("Preferred Commit signing?").
 					Value(&signing).
@@ -146,9 +200,33 @@ func promptForm() (err error) {
 						huh.NewOption("SSH", true),
 					),
 			),
+
+			// which key to use, if any were found on disk
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title("Which SSH key do you want to use?").
+					Value(&sshKeyChoice).
+					Options(keyOptions...),
+			).WithHideFunc(func() bool { return !needsKey() || len(existingKeys) == 0 }),
+
+			// file for SSH key
+			huh.NewGroup(
+				huh.NewInput().
+					Title("File in which to save SSH key?").
+					Value(&sshPath),
+			).WithHideFunc(func() bool { return !needsKey() || sshKeyChoice != generateNewKey }),
 		)
 
-	return form.Run()
+	if err := form.Run(); err != nil {
+		return err
+	}
+
+	useExistingKey = sshKeyChoice != generateNewKey
+	if useExistingKey {
+		sshPath = sshKeyChoice
+	}
+
+	return nil
 }
 
 // Setup git
@@ -184,14 +262,20 @@ func generateSSH() error {
 	return nil
 }
 
-// Setup SSH if selected
-func setupSSH() error {
-	err := generateSSH()
-	if err != nil {
+// loadKeyIntoAgent fingerprints the configured key, starts ssh-agent, and
+// ssh-adds the key into it. Shared by setupSSH and the signing-only branch
+// of setupSigning, since both are "a key now exists on disk and needs to be
+// usable" - not just the SSH-enabled path.
+func loadKeyIntoAgent() error {
+	if err := loadCurrentKey(); err != nil {
 		return err
 	}
 
-	err = exec.Command("ssh-add", sshPath).Run()
+	if err := startSSHAgent(); err != nil {
+		warning("Warning Failed to start ssh-agent: " + err.Error())
+	}
+
+	err := exec.Command("ssh-add", sshPath).Run()
 	if err != nil {
 		warning("Warning Failed to add SSH key using `ssh-add`\nThis can happen if the key is already added. If it isn't, try running `ssh-add %s", sshPath)
 	}
@@ -199,11 +283,27 @@ func setupSSH() error {
 	return nil
 }
 
+// Setup SSH if selected
+func setupSSH() error {
+	if useExistingKey {
+		success("Using existing SSH key at " + sshPath)
+	} else if err := generateSSH(); err != nil {
+		return err
+	}
+
+	return loadKeyIntoAgent()
+}
+
 // Setup Signing if selected
 func setupSigning() error {
 	if ignoreSSH {
-		err := generateSSH()
-		if err != nil {
+		if useExistingKey {
+			success("Using existing SSH key at " + sshPath)
+		} else if err := generateSSH(); err != nil {
+			return err
+		}
+
+		if err := loadKeyIntoAgent(); err != nil {
 			return err
 		}
 	}
@@ -226,6 +326,10 @@ func setupSigning() error {
 	}
 	success("Successfully set git to sign commits by default")
 
+	if err := setupAllowedSigners(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -244,12 +348,21 @@ func almostDone() error {
 		"You have to create seperate keys on github, but can use the same key on your machine\n",
 		"Key:",
 		infoColor.Render(string(pub)),
-		"\nIf you setup SSH, to avoid re-typing ssh key to push, it is recommended to add `ssh-agent` to your command line settings",
-		infoColor.Render("eval \"$(ssh-agent -s)\""),
+		fmt.Sprintf("Fingerprint: %s (%s, %d bits)", currentKey.Fingerprint, currentKey.Type, currentKey.Bits),
 	}
 	for _, line := range lines {
 		fmt.Println(line)
 	}
 
+	if !ignoreSSH || signing {
+		currentUser, err := user.Current()
+		if err != nil {
+			return err
+		}
+		if err := offerShellIntegration(currentUser.HomeDir); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// allowedSignersPath returns the default location git-verify-commit expects
+// for an `allowedSignersFile`.
+func allowedSignersPath(homeDir string) string {
+	return filepath.Join(homeDir, ".config", "git", "allowed_signers")
+}
+
+// addAllowedSigner appends "<email> <keyType> <keyData>" to path, creating
+// it (and its parent dir) if needed, and does nothing if that email/key pair
+// is already present.
+func addAllowedSigner(path, email, keyType, keyData string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, line := range strings.Split(string(existing), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == email && fields[2] == keyData {
+			return nil
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s %s %s\n", email, keyType, keyData)
+	return err
+}
+
+// setupAllowedSigners registers the just-configured key's public half in
+// ~/.config/git/allowed_signers and points git at it, so `git log
+// --show-signature` and `git verify-commit` can actually resolve a
+// principal instead of reporting "No principal matched".
+func setupAllowedSigners() error {
+	pub, err := os.ReadFile(sshPath + ".pub")
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(string(pub))
+	if len(fields) < 2 {
+		return fmt.Errorf("unexpected public key format in %s.pub", sshPath)
+	}
+	keyType, keyData := fields[0], fields[1]
+
+	currentUser, err := user.Current()
+	if err != nil {
+		return err
+	}
+	path := allowedSignersPath(currentUser.HomeDir)
+
+	if err := addAllowedSigner(path, email, keyType, keyData); err != nil {
+		return err
+	}
+
+	if err := exec.Command("git", "config", gitContext, "gpg.ssh.allowedSignersFile", path).Run(); err != nil {
+		return err
+	}
+	success("Successfully configured allowed_signers file")
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddAllowedSigner(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "allowed_signers")
+
+	if err := addAllowedSigner(path, "me@example.com", "ssh-ed25519", "AAAAkey1"); err != nil {
+		t.Fatalf("first add: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "me@example.com ssh-ed25519 AAAAkey1\n"
+	if string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestAddAllowedSignerDedupesSameEmailAndKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "allowed_signers")
+
+	if err := addAllowedSigner(path, "me@example.com", "ssh-ed25519", "AAAAkey1"); err != nil {
+		t.Fatalf("first add: %v", err)
+	}
+	if err := addAllowedSigner(path, "me@example.com", "ssh-ed25519", "AAAAkey1"); err != nil {
+		t.Fatalf("second add: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(string(got), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected dedup to keep a single line, got %q", got)
+	}
+}
+
+func TestAddAllowedSignerAppendsDistinctEntries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "allowed_signers")
+
+	if err := addAllowedSigner(path, "me@example.com", "ssh-ed25519", "AAAAkey1"); err != nil {
+		t.Fatalf("first add: %v", err)
+	}
+	// Same email, different key - e.g. a second machine's key - should not
+	// be treated as a duplicate of the first.
+	if err := addAllowedSigner(path, "me@example.com", "ssh-ed25519", "AAAAkey2"); err != nil {
+		t.Fatalf("second add: %v", err)
+	}
+	// Different email, same key.
+	if err := addAllowedSigner(path, "other@example.com", "ssh-ed25519", "AAAAkey1"); err != nil {
+		t.Fatalf("third add: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(string(got), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 distinct lines, got %q", got)
+	}
+}
+
+func TestAllowedSignersPath(t *testing.T) {
+	got := allowedSignersPath("/home/test")
+	want := filepath.Join("/home/test", ".config", "git", "allowed_signers")
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}